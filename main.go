@@ -1,21 +1,29 @@
 package main  // Declares this as an executable program
 
 import (
-	"encoding/json"  // For JSON encoding/decoding
-	"flag"           // For command-line flag parsing
-	"fmt"            // For formatted I/O
-	"net"            // For network operations
-	"strconv"        // For string conversions
-	"strings"        // For string manipulation
-	"sync"           // For synchronization primitives
-	"time"           // For time-related operations
+	"flag"    // For command-line flag parsing
+	"fmt"     // For formatted I/O
+	"strconv" // For string conversions
+	"strings" // For string manipulation
+	"time"    // For time-related operations
 )
 
 type ScanResult struct {  // Stores results for each port scan
-	Target string `json:"target"`  // Target hostname/IP
-	Port   int    `json:"port"`    // Port number
-	Open   bool   `json:"open"`    // Whether port is open
-	Banner string `json:"banner,omitempty"`  // Service banner if available
+	Target   string       `json:"target"`  // Target hostname/IP
+	Port     int          `json:"port"`    // Port number
+	Protocol string       `json:"protocol"`          // "tcp" or "udp"
+	Open     bool         `json:"open"`    // Whether port is confirmed open (UDP open|filtered is reported via Status only)
+	Status   string       `json:"status,omitempty"`  // UDP only: "open", "open|filtered", or "closed"
+	Banner   string       `json:"banner,omitempty"`  // Service banner if available
+	Response string       `json:"response,omitempty"` // UDP only: raw response bytes, hex-encoded
+	Service  *ServiceInfo `json:"service,omitempty"`  // Structured fingerprint from a Prober, if one matched
+}
+
+// ServiceInfo is the structured output of a Prober: what it identified the
+// service as, plus whatever protocol-specific detail it captured.
+type ServiceInfo struct {
+	Name    string         `json:"name"`
+	Details map[string]any `json:"details,omitempty"`
 }
 
 type ScanSummary struct {  // Stores summary of the scan
@@ -27,29 +35,6 @@ type ScanSummary struct {  // Stores summary of the scan
 	TimeoutSeconds int      `json:"timeout_seconds"` // Timeout setting
 }
 
-func worker(wg *sync.WaitGroup, tasks chan int, results chan ScanResult,target string, timeout time.Duration, mutex *sync.Mutex, progress *int) {
-	defer wg.Done()  // Signal completion when worker exits
-	for port := range tasks {  // Process ports from task channel
-		result := ScanResult{Target: target, Port: port}
-		// Try TCP connection with timeout
-		conn, err := net.DialTimeout("tcp", net.JoinHostPort(target, strconv.Itoa(port)), timeout)
-		if err == nil {  // If connection succeeded
-			result.Open = true
-			conn.SetReadDeadline(time.Now().Add(timeout))  // Set read timeout
-			banner := make([]byte, 1024)  // Buffer for banner
-			n, _ := conn.Read(banner)  // Read initial response
-			if n > 0 {
-				result.Banner = strings.TrimSpace(string(banner[:n]))  // Store banner
-			}
-			conn.Close()  // Close connection
-		}
-		results <- result  // Send result to output channel
-		mutex.Lock()  // Safely update progress counter
-		*progress++
-		mutex.Unlock()
-	}
-}
-
 func main() {
 	// Command-line flag definitions
 	target := flag.String("target", "scanme.nmap.org", "Target hostname or IP")
@@ -60,14 +45,35 @@ func main() {
 	timeout := flag.Int("timeout", 5, "Connection timeout in seconds")
 	portsList := flag.String("ports", "", "Comma-separated list of ports")
 	jsonOutput := flag.Bool("json", false, "Output results in JSON format")
+	proto := flag.String("proto", "tcp", "Protocol to scan: tcp, udp, or both")
+	targetFile := flag.String("target-file", "", "File with newline-delimited targets, e.g. @hosts.txt")
+	exclude := flag.String("exclude", "", "Comma-separated hosts/CIDRs/ranges to exclude from the scan")
+	dnsTimeout := flag.Int("dns-timeout", 3, "DNS resolution timeout in seconds")
+	rateLimit := flag.Float64("rate", 0, "Max dial attempts/sec across all targets (0 = unlimited), adaptively backed off on high failure rates")
+	outputPath := flag.String("output", "", "Write NDJSON results here instead of stdout (only used with -json)")
+	promPort := flag.Int("promPort", 0, "Port to expose Prometheus metrics on (0 = disabled)")
+	profPort := flag.Int("profPort", 0, "Port to expose net/http/pprof on (0 = disabled)")
 	flag.Parse()  // Parse command-line flags
 
-	// Process target list
-	var targetsToScan []string
-	if *targets != "" {
-		targetsToScan = strings.Split(*targets, ",")
-	} else {
-		targetsToScan = []string{*target}
+	startMetricsServer(*promPort, *profPort)
+
+	// Validate the requested protocol mode
+	scanTCP := *proto == "tcp" || *proto == "both"
+	scanUDP := *proto == "udp" || *proto == "both"
+	if !scanTCP && !scanUDP {
+		fmt.Printf("Invalid -proto %q, must be tcp, udp, or both\n", *proto)
+		return
+	}
+
+	// Process target list: expands CIDRs/ranges/files and resolves hostnames
+	targetsToScan, err := buildTargetList(*target, *targets, *targetFile, *exclude, time.Duration(*dnsTimeout)*time.Second)
+	if err != nil {
+		fmt.Println("Error building target list:", err)
+		return
+	}
+	if len(targetsToScan) == 0 {
+		fmt.Println("No targets to scan")
+		return
 	}
 
 	// Process port list/range
@@ -96,77 +102,65 @@ func main() {
 		}
 	}
 
+	// Set up NDJSON streaming up front so results can be written as they
+	// arrive instead of being buffered in memory until the scan finishes
+	var ndjson *ndjsonWriter
+	if *jsonOutput {
+		w, closeWriter, err := openOutputWriter(*outputPath)
+		if err != nil {
+			fmt.Println("Error opening output:", err)
+			return
+		}
+		defer closeWriter()
+		ndjson = newNDJSONWriter(w)
+	}
+
 	startTime := time.Now()  // Record start time
-	var allResults []ScanResult
 	openPorts := 0  // Counter for open ports
 
-	// Process each target
-	for _, target := range targetsToScan {
-		totalPorts := len(portsToScan)
-		tasks := make(chan int, *workers)  // Buffered channel for ports to scan
-		results := make(chan ScanResult, totalPorts)  // Buffered channel for results
-		var wg sync.WaitGroup  // WaitGroup to track workers
-		var mutex sync.Mutex  // Mutex for progress counter
-		progress := 0  // Progress counter
+	// Build the protocol list once; a single global pool then scans every
+	// (target, port, protocol) tuple instead of a fresh pool per target
+	var protocols []string
+	if scanTCP {
+		protocols = append(protocols, "tcp")
+	}
+	if scanUDP {
+		protocols = append(protocols, "udp")
+	}
 
-		// Launch worker goroutines
-		for i := 0; i < *workers; i++ {
-			wg.Add(1)
-			go worker(&wg, tasks, results, target, time.Duration(*timeout)*time.Second, &mutex, &progress)
+	for result := range startGlobalScan(targetsToScan, portsToScan, protocols, *workers, time.Duration(*timeout)*time.Second, *rateLimit) {
+		if result.Open {
+			openPorts++
 		}
-
-		// Feed ports to workers
-		go func() {
-			for _, port := range portsToScan {
-				tasks <- port
-			}
-			close(tasks)  // Close channel when done
-		}()
-
-		// Collect results
-		var scanResults []ScanResult
-		for i := 0; i < totalPorts; i++ {
-			result := <-results
-			if result.Open {
-				openPorts++
-				scanResults = append(scanResults, result)
-			}
+		// UDP results are worth reporting even when Open is false - Status
+		// still distinguishes "open|filtered" from a confirmed "closed", and
+		// dropping those here would hide the very classification -proto udp
+		// exists to produce.
+		if !result.Open && result.Protocol != "udp" {
+			continue
 		}
-		wg.Wait()  // Wait for all workers to finish
-		allResults = append(allResults, scanResults...)
-	}
-
-	// Output results
-	if *jsonOutput {
-		summary := ScanSummary{
-			Targets:        targetsToScan,
-			OpenPorts:      openPorts,
-			TotalPorts:     len(portsToScan),
-			TimeTaken:      fmt.Sprintf("%.2f seconds", time.Since(startTime).Seconds()),
-			Workers:        *workers,
-			TimeoutSeconds: *timeout,
+		if ndjson != nil {
+			ndjson.WriteResult(result)
 		}
+	}
 
-		output := struct {
-			Results []ScanResult `json:"results"`
-			Summary ScanSummary  `json:"summary"`
-		}{
-			Results: allResults,
-			Summary: summary,
-		}
+	summary := ScanSummary{
+		Targets:        targetsToScan,
+		OpenPorts:      openPorts,
+		TotalPorts:     len(portsToScan),
+		TimeTaken:      fmt.Sprintf("%.2f seconds", time.Since(startTime).Seconds()),
+		Workers:        *workers,
+		TimeoutSeconds: *timeout,
+	}
 
-		jsonData, err := json.MarshalIndent(output, "", "  ")
-		if err != nil {
-			fmt.Println("Error generating JSON output:", err)
-		} else {
-			fmt.Println(string(jsonData))
-		}
+	if ndjson != nil {
+		ndjson.WriteSummary(summary)
 	} else {  // Human-readable output
 		fmt.Println("\nScan Summary:")
 		fmt.Printf("Targets: %v\n", targetsToScan)
 		fmt.Printf("Open ports: %d\n", openPorts)
 		fmt.Printf("Total ports scanned: %d\n", len(portsToScan))
-		fmt.Printf("Time taken: %.2f seconds\n", time.Since(startTime).Seconds())
+		fmt.Printf("Time taken: %s\n", summary.TimeTaken)
 		fmt.Printf("Workers: %d\n", *workers)
 		fmt.Printf("Timeout: %d seconds\n", *timeout)
 	}
@@ -0,0 +1,101 @@
+package main // Prometheus metrics and pprof endpoints for observing long-running scans
+
+import (
+	"errors"        // For unwrapping net/syscall errors
+	"fmt"           // For formatted I/O
+	"log"           // For logging server failures
+	"net"           // For network operations
+	"net/http"      // For the metrics/pprof HTTP servers
+	"net/http/pprof" // For exposing Go runtime profiles
+	"strconv"       // For string conversions
+	"syscall"       // For classifying dial errors
+	"time"          // For time-related operations
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	portsScannedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "scanner_ports_scanned_total",
+		Help: "Total number of ports probed, across all targets and protocols.",
+	})
+	portsOpenTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scanner_ports_open_total",
+		Help: "Total number of ports found open, labeled by target and port.",
+	}, []string{"target", "port"})
+	dialErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scanner_dial_errors_total",
+		Help: "Total number of failed dial attempts, labeled by failure reason.",
+	}, []string{"reason"})
+	dialDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "scanner_dial_duration_seconds",
+		Help:    "Time spent dialing a target:port, successful or not.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+// dialWithMetrics wraps net.DialTimeout, recording dial duration and a
+// failure-reason counter for the Prometheus /metrics endpoint.
+func dialWithMetrics(network, address string, timeout time.Duration) (net.Conn, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout(network, address, timeout)
+	dialDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		dialErrorsTotal.WithLabelValues(classifyDialErr(err)).Inc()
+	}
+	return conn, err
+}
+
+// classifyDialErr buckets a dial error into a small label set - an
+// unbounded error string would blow up the counter's cardinality.
+func classifyDialErr(err error) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return "refused"
+	}
+	if errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH) {
+		return "unreachable"
+	}
+	return "other"
+}
+
+// recordPortResult updates the scanned/open counters for a single result.
+func recordPortResult(result ScanResult) {
+	portsScannedTotal.Inc()
+	if result.Open {
+		portsOpenTotal.WithLabelValues(result.Target, strconv.Itoa(result.Port)).Inc()
+	}
+}
+
+// startMetricsServer starts an HTTP server exposing Prometheus metrics on
+// promPort and, if profPort is set, net/http/pprof's profiling endpoints
+// on a separate port. A port of 0 disables the corresponding server.
+func startMetricsServer(promPort, profPort int) {
+	if promPort > 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		go serveOrLog(fmt.Sprintf(":%d", promPort), mux)
+	}
+
+	if profPort > 0 {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		go serveOrLog(fmt.Sprintf(":%d", profPort), mux)
+	}
+}
+
+// serveOrLog runs an HTTP server and logs if it ever exits unexpectedly.
+func serveOrLog(addr string, handler http.Handler) {
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Printf("server on %s stopped: %v", addr, err)
+	}
+}
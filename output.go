@@ -0,0 +1,53 @@
+package main // Streaming NDJSON output
+
+import (
+	"encoding/json" // For JSON encoding/decoding
+	"fmt"           // For formatted I/O
+	"io"            // For writing to stdout or a file
+	"os"            // For opening the -output file
+)
+
+// resultLine and summaryLine are the two kinds of objects written to the
+// NDJSON stream, discriminated by "type" so a consumer can tell them apart
+// without caring about field overlap.
+type resultLine struct {
+	Type string `json:"type"`
+	ScanResult
+}
+
+type summaryLine struct {
+	Type string `json:"type"`
+	ScanSummary
+}
+
+// openOutputWriter returns stdout, or the -output file if one was given.
+func openOutputWriter(path string) (io.Writer, func() error, error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening -output file: %w", err)
+	}
+	return file, file.Close, nil
+}
+
+// ndjsonWriter streams ScanResults and a final summary as newline-delimited
+// JSON, one object per line, as each result arrives rather than buffering
+// the whole scan in memory.
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{encoder: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) WriteResult(result ScanResult) error {
+	return n.encoder.Encode(resultLine{Type: "result", ScanResult: result})
+}
+
+func (n *ndjsonWriter) WriteSummary(summary ScanSummary) error {
+	return n.encoder.Encode(summaryLine{Type: "summary", ScanSummary: summary})
+}
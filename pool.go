@@ -0,0 +1,79 @@
+package main // Global work queue: one pool of workers scanning across all targets
+
+import (
+	"context" // For the adaptive limiter's Wait call
+	"sync"    // For synchronization primitives
+	"time"    // For time-related operations
+)
+
+// scanTask is one unit of work: a single port on a single target, probed
+// with a single protocol.
+type scanTask struct {
+	Target   string
+	Port     int
+	Protocol string // "tcp" or "udp"
+}
+
+// globalWorker pulls tasks from the shared queue until it's closed, probing
+// each with the protocol-appropriate prober and feeding dial outcomes back
+// into the rate limiter so it can adapt.
+func globalWorker(wg *sync.WaitGroup, tasks chan scanTask, results chan ScanResult, timeout time.Duration, limiter *AdaptiveLimiter) {
+	defer wg.Done() // Signal completion when worker exits
+	for task := range tasks {
+		if err := limiter.Wait(context.Background()); err != nil {
+			continue // Limiter context never gets cancelled in practice
+		}
+
+		var result ScanResult
+		var dialFailed bool
+		if task.Protocol == "udp" {
+			result, dialFailed = probeUDPPort(task.Target, task.Port, timeout)
+		} else {
+			result, dialFailed = probeTCPPort(task.Target, task.Port, timeout)
+		}
+		limiter.RecordResult(dialFailed)
+		recordPortResult(result)
+
+		results <- result
+	}
+}
+
+// startGlobalScan feeds every (target, port, protocol) tuple into one
+// shared queue so a single pool of workers scans across all targets at
+// once, instead of a fresh pool being spun up per target. ratePerSec <= 0
+// disables rate limiting entirely. The returned channel is closed once
+// every tuple has been scanned, so callers can range over it to stream
+// results as they arrive rather than waiting for the whole scan to finish.
+func startGlobalScan(targets []string, ports []int, protocols []string, workers int, timeout time.Duration, ratePerSec float64) <-chan ScanResult {
+	tasks := make(chan scanTask, workers)     // Buffered channel for (target, port, protocol) tuples
+	results := make(chan ScanResult, workers) // Buffered channel for results
+	var wg sync.WaitGroup                     // WaitGroup to track workers
+
+	limiter := NewAdaptiveLimiter(ratePerSec)
+
+	// Launch worker goroutines
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go globalWorker(&wg, tasks, results, timeout, limiter)
+	}
+
+	// Feed every tuple to the shared queue
+	go func() {
+		for _, target := range targets {
+			for _, port := range ports {
+				for _, protocol := range protocols {
+					tasks <- scanTask{Target: target, Port: port, Protocol: protocol}
+				}
+			}
+		}
+		close(tasks) // Close channel when done
+	}()
+
+	// Close results once every worker has drained the queue
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,92 @@
+package main // Adaptive rate limiting for the global scan queue
+
+import (
+	"context" // For bounding the limiter wait against cancellation
+	"math"    // For capping the recovered rate at the configured ceiling
+	"sync"    // For synchronization primitives
+
+	"golang.org/x/time/rate" // Token-bucket limiter
+)
+
+// adaptiveWindowSize is how many recent dial attempts are considered when
+// deciding whether to back off or recover.
+const adaptiveWindowSize = 500
+
+// adaptiveFailureThreshold is the failure ratio over the window above which
+// the limiter halves its rate.
+const adaptiveFailureThreshold = 0.4
+
+// AdaptiveLimiter wraps a token-bucket rate.Limiter and halves its rate
+// when the observed dial-failure ratio over a sliding window crosses
+// adaptiveFailureThreshold, doubling it back (up to the configured base
+// rate) once the ratio recovers. A nil *AdaptiveLimiter is a valid,
+// unlimited no-op, so callers don't need to special-case -rate=0.
+type AdaptiveLimiter struct {
+	mu          sync.Mutex
+	limiter     *rate.Limiter
+	baseRate    float64
+	currentRate float64
+	window      []bool // true = dial failed, oldest first
+	backedOff   bool
+}
+
+// NewAdaptiveLimiter builds a limiter enforcing ratePerSec, or returns nil
+// (meaning "no limit") when ratePerSec <= 0.
+func NewAdaptiveLimiter(ratePerSec float64) *AdaptiveLimiter {
+	if ratePerSec <= 0 {
+		return nil
+	}
+	return &AdaptiveLimiter{
+		limiter:     rate.NewLimiter(rate.Limit(ratePerSec), int(math.Max(1, ratePerSec))),
+		baseRate:    ratePerSec,
+		currentRate: ratePerSec,
+	}
+}
+
+// Wait blocks until a token is available, or ctx is done.
+func (a *AdaptiveLimiter) Wait(ctx context.Context) error {
+	if a == nil {
+		return nil // Unlimited
+	}
+	return a.limiter.Wait(ctx)
+}
+
+// RecordResult feeds a dial outcome into the sliding window and adjusts the
+// rate once a full window has accumulated.
+func (a *AdaptiveLimiter) RecordResult(dialFailed bool) {
+	if a == nil {
+		return // Unlimited
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.window = append(a.window, dialFailed)
+	if len(a.window) < adaptiveWindowSize {
+		return // Not enough samples yet
+	}
+	a.window = a.window[len(a.window)-adaptiveWindowSize:]
+
+	failures := 0
+	for _, failed := range a.window {
+		if failed {
+			failures++
+		}
+	}
+	failureRatio := float64(failures) / float64(len(a.window))
+
+	switch {
+	case failureRatio > adaptiveFailureThreshold && !a.backedOff:
+		a.currentRate /= 2
+		a.limiter.SetLimit(rate.Limit(a.currentRate))
+		a.backedOff = true
+		a.window = a.window[:0] // Reset so one noisy window doesn't cascade
+	case failureRatio <= adaptiveFailureThreshold && a.backedOff:
+		a.currentRate = math.Min(a.currentRate*2, a.baseRate)
+		a.limiter.SetLimit(rate.Limit(a.currentRate))
+		if a.currentRate >= a.baseRate {
+			a.backedOff = false
+		}
+		a.window = a.window[:0]
+	}
+}
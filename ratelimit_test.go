@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestNewAdaptiveLimiterDisabledForNonPositiveRate(t *testing.T) {
+	if l := NewAdaptiveLimiter(0); l != nil {
+		t.Errorf("NewAdaptiveLimiter(0) = %v, want nil", l)
+	}
+	if l := NewAdaptiveLimiter(-5); l != nil {
+		t.Errorf("NewAdaptiveLimiter(-5) = %v, want nil", l)
+	}
+}
+
+func TestAdaptiveLimiterRecordResultNilIsNoop(t *testing.T) {
+	var l *AdaptiveLimiter
+	l.RecordResult(true) // Must not panic
+}
+
+func TestAdaptiveLimiterBacksOffOverThreshold(t *testing.T) {
+	l := NewAdaptiveLimiter(100)
+
+	// A window below adaptiveFailureThreshold shouldn't trigger backoff.
+	recordN(l, adaptiveWindowSize-1, false)
+	recordN(l, 1, true)
+	if l.backedOff {
+		t.Fatalf("backed off after a window under the failure threshold")
+	}
+
+	// Push the ratio back over the threshold with a fresh full window.
+	recordN(l, adaptiveWindowSize, true)
+	if !l.backedOff {
+		t.Fatalf("expected backoff after a window over the failure threshold")
+	}
+	if l.currentRate != 50 {
+		t.Errorf("currentRate = %v, want 50 (halved from 100)", l.currentRate)
+	}
+}
+
+func TestAdaptiveLimiterRecoversAfterBackoff(t *testing.T) {
+	l := NewAdaptiveLimiter(100)
+	recordN(l, adaptiveWindowSize, true) // Force a backoff
+	if !l.backedOff || l.currentRate != 50 {
+		t.Fatalf("setup failed: backedOff=%v currentRate=%v", l.backedOff, l.currentRate)
+	}
+
+	// A fully healthy window should double the rate back, capped at baseRate.
+	recordN(l, adaptiveWindowSize, false)
+	if l.backedOff {
+		t.Errorf("expected recovery to clear backedOff once currentRate reached baseRate")
+	}
+	if l.currentRate != 100 {
+		t.Errorf("currentRate = %v, want 100 (recovered to baseRate)", l.currentRate)
+	}
+}
+
+// recordN feeds n identical dial outcomes into the limiter.
+func recordN(l *AdaptiveLimiter, n int, dialFailed bool) {
+	for i := 0; i < n; i++ {
+		l.RecordResult(dialFailed)
+	}
+}
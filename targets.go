@@ -0,0 +1,213 @@
+package main // Target parsing: CIDR blocks, dash ranges, target files, and exclusions
+
+import (
+	"bufio"  // For reading target files line by line
+	"context" // For bounding DNS lookups with a timeout
+	"fmt"    // For formatted I/O
+	"net"    // For network operations
+	"os"     // For reading target files
+	"strings" // For string manipulation
+	"time"   // For time-related operations
+)
+
+// loadTargetFile reads newline-delimited targets from path, skipping blank
+// lines and "#" comments. The "-target-file" flag's value may be prefixed
+// with "@" (e.g. "@hosts.txt"), a convention borrowed from curl/ffuf.
+func loadTargetFile(path string) ([]string, error) {
+	path = strings.TrimPrefix(path, "@")
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading target file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue // Skip blank lines and comments
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// expandCIDR returns every address in a CIDR block, e.g. "10.0.0.0/24",
+// including the network and broadcast addresses.
+func expandCIDR(cidr string) ([]string, error) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+
+	var hosts []string
+	for addr := ip.Mask(ipnet.Mask); ipnet.Contains(addr); incIP(addr) {
+		hosts = append(hosts, addr.String())
+	}
+	return hosts, nil
+}
+
+// incIP increments an IP address in place, used to walk a CIDR block.
+func incIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break // No carry needed
+		}
+	}
+}
+
+// expandRange returns every address in a dash range, e.g.
+// "10.0.0.1-10.0.0.50". Both sides must be full IPv4 addresses.
+func expandRange(rangeStr string) ([]string, error) {
+	parts := strings.SplitN(rangeStr, "-", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid range %q", rangeStr)
+	}
+
+	start := net.ParseIP(strings.TrimSpace(parts[0])).To4()
+	end := net.ParseIP(strings.TrimSpace(parts[1])).To4()
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("invalid range %q: both ends must be IPv4 addresses", rangeStr)
+	}
+
+	startNum := ipToUint32(start)
+	endNum := ipToUint32(end)
+	if startNum > endNum {
+		return nil, fmt.Errorf("invalid range %q: start is after end", rangeStr)
+	}
+
+	var hosts []string
+	for n := startNum; n <= endNum; n++ {
+		hosts = append(hosts, uint32ToIP(n).String())
+	}
+	return hosts, nil
+}
+
+// ipToUint32 converts an IPv4 address to its big-endian integer form.
+func ipToUint32(ip net.IP) uint32 {
+	return uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+}
+
+// uint32ToIP is the inverse of ipToUint32.
+func uint32ToIP(n uint32) net.IP {
+	return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// expandTarget expands a single target entry (CIDR, dash range, or a plain
+// host/IP) into one or more host strings.
+func expandTarget(entry string) ([]string, error) {
+	switch {
+	case strings.Contains(entry, "/"):
+		return expandCIDR(entry)
+	case strings.Contains(entry, "-") && net.ParseIP(strings.TrimSpace(strings.SplitN(entry, "-", 2)[0])) != nil:
+		return expandRange(entry) // Only when the left side is an IP, so hostnames like "my-host" stay plain
+	default:
+		return []string{entry}, nil
+	}
+}
+
+// resolveHost resolves host to its IP addresses via net.LookupHost, bounded
+// by timeout. If host is already a literal IP, it's returned as-is without
+// a lookup.
+func resolveHost(host string, timeout time.Duration) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resolver := net.Resolver{}
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", host, err)
+	}
+	return addrs, nil
+}
+
+// buildTargetList turns the raw -target/-targets/-target-file/-exclude flag
+// values into a deduplicated, resolved list of IPs ready for the worker
+// pool. Unresolvable hosts are dropped with a warning rather than aborting
+// the whole scan; excluded hosts are dropped silently.
+func buildTargetList(target, targets, targetFile, exclude string, dnsTimeout time.Duration) ([]string, error) {
+	var rawEntries []string
+	if targets != "" {
+		rawEntries = append(rawEntries, strings.Split(targets, ",")...)
+	} else if target != "" {
+		rawEntries = append(rawEntries, target)
+	}
+
+	if targetFile != "" {
+		fileEntries, err := loadTargetFile(targetFile)
+		if err != nil {
+			return nil, err
+		}
+		rawEntries = append(rawEntries, fileEntries...)
+	}
+
+	excludeSet, err := buildExcludeSet(exclude, dnsTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool) // De-duplicate expanded/resolved hosts
+	var resolved []string
+	for _, entry := range rawEntries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		hosts, err := expandTarget(entry)
+		if err != nil {
+			fmt.Printf("Skipping target %q: %v\n", entry, err)
+			continue
+		}
+		for _, host := range hosts {
+			ips, err := resolveHost(host, dnsTimeout)
+			if err != nil {
+				fmt.Printf("Skipping target %q: %v\n", host, err)
+				continue
+			}
+			for _, ip := range ips {
+				if excludeSet[ip] || seen[ip] {
+					continue
+				}
+				seen[ip] = true
+				resolved = append(resolved, ip)
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// buildExcludeSet expands the comma-separated -exclude flag (which accepts
+// the same CIDR/range/hostname syntax as -targets) into a set of excluded IPs.
+func buildExcludeSet(exclude string, dnsTimeout time.Duration) (map[string]bool, error) {
+	excludeSet := make(map[string]bool)
+	if exclude == "" {
+		return excludeSet, nil
+	}
+
+	for _, entry := range strings.Split(exclude, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		hosts, err := expandTarget(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -exclude entry %q: %w", entry, err)
+		}
+		for _, host := range hosts {
+			ips, err := resolveHost(host, dnsTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -exclude entry %q: %w", host, err)
+			}
+			for _, ip := range ips {
+				excludeSet[ip] = true
+			}
+		}
+	}
+	return excludeSet, nil
+}
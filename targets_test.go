@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestExpandCIDR(t *testing.T) {
+	tests := []struct {
+		cidr    string
+		want    []string
+		wantErr bool
+	}{
+		{cidr: "10.0.0.0/30", want: []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{cidr: "192.168.1.5/32", want: []string{"192.168.1.5"}},
+		{cidr: "not-a-cidr", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := expandCIDR(tt.cidr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expandCIDR(%q): expected error, got none", tt.cidr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("expandCIDR(%q): unexpected error: %v", tt.cidr, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandCIDR(%q) = %v, want %v", tt.cidr, got, tt.want)
+		}
+	}
+}
+
+func TestExpandRange(t *testing.T) {
+	tests := []struct {
+		rangeStr string
+		want     []string
+		wantErr  bool
+	}{
+		{rangeStr: "10.0.0.1-10.0.0.3", want: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{rangeStr: "10.0.0.5-10.0.0.5", want: []string{"10.0.0.5"}},
+		{rangeStr: "10.0.0.5-10.0.0.1", wantErr: true}, // start after end
+		{rangeStr: "not-a-range", wantErr: true},
+		{rangeStr: "10.0.0.1-not-an-ip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := expandRange(tt.rangeStr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expandRange(%q): expected error, got none", tt.rangeStr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("expandRange(%q): unexpected error: %v", tt.rangeStr, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandRange(%q) = %v, want %v", tt.rangeStr, got, tt.want)
+		}
+	}
+}
+
+func TestIPToUint32RoundTrip(t *testing.T) {
+	tests := []struct {
+		ip string
+		n  uint32
+	}{
+		{ip: "0.0.0.0", n: 0},
+		{ip: "0.0.0.1", n: 1},
+		{ip: "255.255.255.255", n: 0xFFFFFFFF},
+		{ip: "10.0.0.1", n: 10<<24 | 1},
+	}
+
+	for _, tt := range tests {
+		ip := net.ParseIP(tt.ip).To4()
+		if got := ipToUint32(ip); got != tt.n {
+			t.Errorf("ipToUint32(%q) = %d, want %d", tt.ip, got, tt.n)
+		}
+		if got := uint32ToIP(tt.n).String(); got != tt.ip {
+			t.Errorf("uint32ToIP(%d) = %q, want %q", tt.n, got, tt.ip)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main // TCP probing, split out from main.go once the worker pool went global
+
+import (
+	"net"     // For network operations
+	"strconv" // For string conversions
+	"time"    // For time-related operations
+)
+
+// probeTCPPort connects to target:port and runs the port's registered
+// Prober (or the banner-read fallback) against the connection. It reports
+// dialFailed separately from ScanResult.Open so the caller can feed dial
+// failures into the adaptive rate limiter without conflating them with a
+// legitimately closed port; dialFailed only reflects a genuine timeout, not
+// an instant ECONNREFUSED, so a mostly-closed scan doesn't look unhealthy.
+func probeTCPPort(target string, port int, timeout time.Duration) (result ScanResult, dialFailed bool) {
+	result = ScanResult{Target: target, Port: port, Protocol: "tcp"}
+
+	conn, err := dialWithMetrics("tcp", net.JoinHostPort(target, strconv.Itoa(port)), timeout)
+	if err != nil {
+		// A closed port refusing the connection is the expected outcome for
+		// most of a scan's ports, not a sign of network trouble - only a
+		// genuine timeout should count against the adaptive rate limiter.
+		return result, classifyDialErr(err) == "timeout"
+	}
+	defer conn.Close()
+
+	result.Open = true
+	conn.SetDeadline(time.Now().Add(timeout)) // Bound every read/write the prober does
+
+	service, details, err := proberFor(port).Probe(conn, port)
+	if err == nil && (service != "" || len(details) > 0) {
+		result.Service = &ServiceInfo{Name: service, Details: details}
+		if banner, ok := details["banner"].(string); ok {
+			result.Banner = banner // Preserve the plain banner field for simple consumers
+		}
+	}
+	return result, false
+}
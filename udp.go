@@ -0,0 +1,143 @@
+package main // UDP scanning support, split out from main.go to keep the probe table together
+
+import (
+	"encoding/hex" // For hex-encoding raw UDP responses
+	"errors"       // For unwrapping syscall errors from net.OpError
+	"net"          // For network operations
+	"strconv"      // For string conversions
+	"syscall"      // For detecting ICMP port-unreachable via ECONNREFUSED
+	"time"         // For time-related operations
+)
+
+// udpProbes maps well-known UDP ports to a minimal valid request payload.
+// A bare UDP DialTimeout almost always "succeeds" without proving the port
+// is actually listening, so we send something the service should answer.
+var udpProbes = map[int][]byte{
+	53:   dnsProbe(),     // DNS: standard query
+	123:  ntpProbe(),     // NTP: client request packet
+	161:  snmpProbe(),    // SNMP: GetRequest for sysDescr
+	137:  netbiosProbe(), // NetBIOS Name Service query
+	1900: ssdpProbe(),    // SSDP: M-SEARCH
+	500:  ikeProbe(),     // IKE: ISAKMP header with no payloads
+}
+
+// dnsProbe builds a minimal DNS query for the root zone (type A).
+func dnsProbe() []byte {
+	return []byte{
+		0x00, 0x00, // transaction ID
+		0x01, 0x00, // standard query, recursion desired
+		0x00, 0x01, // questions: 1
+		0x00, 0x00, // answer RRs: 0
+		0x00, 0x00, // authority RRs: 0
+		0x00, 0x00, // additional RRs: 0
+		0x00,       // root name
+		0x00, 0x01, // type A
+		0x00, 0x01, // class IN
+	}
+}
+
+// ntpProbe builds a 48-byte SNTP client request (mode 3, version 3).
+func ntpProbe() []byte {
+	packet := make([]byte, 48)
+	packet[0] = 0x1B // LI = 0, VN = 3, Mode = 3 (client)
+	return packet
+}
+
+// snmpProbe builds a minimal SNMPv1 GetRequest for sysDescr.0 using the "public" community.
+func snmpProbe() []byte {
+	return []byte{
+		0x30, 0x26, // SEQUENCE
+		0x02, 0x01, 0x00, // version: 1 (SNMPv1)
+		0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c', // community
+		0xA0, 0x19, // GetRequest PDU
+		0x02, 0x01, 0x01, // request ID
+		0x02, 0x01, 0x00, // error status
+		0x02, 0x01, 0x00, // error index
+		0x30, 0x0E, // varbind list
+		0x30, 0x0C,
+		0x06, 0x08, 0x2B, 0x06, 0x01, 0x02, 0x01, 0x01, 0x01, 0x00, // OID 1.3.6.1.2.1.1.1.0 (sysDescr)
+		0x05, 0x00, // NULL value
+	}
+}
+
+// netbiosProbe builds a NetBIOS Name Service "NBSTAT" query for the wildcard name.
+func netbiosProbe() []byte {
+	return []byte{
+		0x80, 0xF0, // transaction ID
+		0x00, 0x10, // flags: broadcast, standard query
+		0x00, 0x01, // questions: 1
+		0x00, 0x00, // answer RRs: 0
+		0x00, 0x00, // authority RRs: 0
+		0x00, 0x00, // additional RRs: 0
+		0x20, 'C', 'K', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', // encoded wildcard name
+		'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 0x00,
+		0x00, 0x21, // type: NBSTAT
+		0x00, 0x01, // class IN
+	}
+}
+
+// ssdpProbe builds an SSDP M-SEARCH discovery request.
+func ssdpProbe() []byte {
+	return []byte("M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: 239.255.255.250:1900\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 1\r\n" +
+		"ST: ssdp:all\r\n\r\n")
+}
+
+// ikeProbe builds a bare ISAKMP header (no proposals), enough to elicit a response or reject.
+func ikeProbe() []byte {
+	packet := make([]byte, 28)
+	packet[16] = 0x01 // next payload: Security Association
+	packet[17] = 0x10 // version 1.0
+	packet[18] = 0x05 // exchange type: Identity Protection
+	return packet
+}
+
+// probeUDPPort sends the port's registered probe (or an empty datagram if
+// none is registered) and classifies the response. dialFailed mirrors
+// probeTCPPort's signal for the adaptive rate limiter.
+func probeUDPPort(target string, port int, timeout time.Duration) (result ScanResult, dialFailed bool) {
+	result = ScanResult{Target: target, Port: port, Protocol: "udp"}
+
+	conn, err := dialWithMetrics("udp", net.JoinHostPort(target, strconv.Itoa(port)), timeout)
+	if err != nil {
+		result.Status = "closed"
+		// Only a genuine timeout should count against the adaptive rate
+		// limiter - an instant ECONNREFUSED is the expected, healthy outcome.
+		return result, classifyDialErr(err) == "timeout"
+	}
+	defer conn.Close()
+
+	payload := udpProbes[port] // nil if we have no known probe for this port
+	if _, err := conn.Write(payload); err != nil {
+		result.Status = "closed"
+		return result, classifyDialErr(err) == "timeout"
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout)) // Set read timeout
+	response := make([]byte, 1024)                // Buffer for the probe response
+	n, err := conn.Read(response)
+	switch {
+	case err == nil && n > 0:
+		// A plausible response arrived - the port is open
+		result.Open = true
+		result.Status = "open"
+		result.Response = hex.EncodeToString(response[:n])
+	case isConnRefused(err):
+		// ICMP port-unreachable surfaced as ECONNREFUSED on the read
+		result.Status = "closed"
+	default:
+		// No response within the deadline - could be open or silently
+		// filtered, so it's not a confirmed open port: leave Open false and
+		// rely on Status for callers that want to see the ambiguous case.
+		result.Status = "open|filtered"
+	}
+	return result, false
+}
+
+// isConnRefused reports whether err ultimately wraps syscall.ECONNREFUSED,
+// which is how an ICMP port-unreachable surfaces on a UDP socket read.
+func isConnRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}